@@ -0,0 +1,126 @@
+// Package checkpoint persists, per channel, the last published record
+// number so Winlogbeat can resume reading an event log across restarts
+// instead of redelivering or dropping events.
+package checkpoint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/elastic/libbeat/logp"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFileName is the name of the checkpoint file Winlogbeat writes
+// under ${path.data} when no explicit path is configured.
+const DefaultFileName = "winlogbeat.yml"
+
+// State is the persisted resume point for a single channel.
+//
+// Both EventLog backends resume via the EventLog.Open(recordNumber)
+// uint64, so RecordNumber is the only field for now. The WinEventLog
+// backend does not yet produce or consume real wevtapi bookmark XML (see
+// WinEventLog.Open); a Bookmark field will be added here once it does,
+// alongside a way to carry it through the EventLog interface.
+type State struct {
+	// RecordNumber is the last record number successfully published for
+	// this channel.
+	RecordNumber uint64 `yaml:"record_number"`
+}
+
+// Checkpoint tracks the State of every channel Winlogbeat is reading and
+// persists it to a YAML file on disk.
+type Checkpoint struct {
+	path string
+
+	mutex sync.Mutex
+	dirty bool
+	state map[string]State
+}
+
+// Open loads the checkpoint file at path, if it exists, and returns a
+// Checkpoint ready to be queried and updated. A missing file is not an
+// error; it simply means every channel starts with a zero-value State.
+func Open(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, state: map[string]State{}}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file '%v': %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &c.state); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file '%v': %w", path, err)
+	}
+	return c, nil
+}
+
+// State returns the persisted State for the given channel name, or the
+// zero value if the channel has no recorded state (e.g. first run, or the
+// channel was added after the last checkpoint was written).
+func (c *Checkpoint) State(name string) State {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.state[name]
+}
+
+// Update records a new State for the given channel. The change is only
+// persisted to disk on the next call to Save.
+func (c *Checkpoint) Update(name string, s State) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.state[name] = s
+	c.dirty = true
+}
+
+// Save atomically rewrites the checkpoint file with the current state of
+// every channel, unless nothing has changed since the last Save. It
+// writes to a temporary file in the same directory and renames it over
+// the destination so that readers (and a concurrently crashing
+// Winlogbeat) never observe a partially written file.
+func (c *Checkpoint) Save() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := yaml.Marshal(c.state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint state: %w", err)
+	}
+
+	dir := filepath.Dir(c.path)
+	tmp, err := ioutil.TempFile(dir, ".winlogbeat-checkpoint-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary checkpoint file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write temporary checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to close temporary checkpoint file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, c.path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to replace checkpoint file '%v': %w", c.path, err)
+	}
+
+	c.dirty = false
+	logp.Debug("checkpoint", "Wrote checkpoint state for %d channel(s) to '%v'",
+		len(c.state), c.path)
+	return nil
+}
@@ -0,0 +1,91 @@
+package checkpoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tempCheckpointPath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "winlogbeat-checkpoint-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, DefaultFileName)
+}
+
+func TestOpenMissingFile(t *testing.T) {
+	c, err := Open(tempCheckpointPath(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, State{}, c.State("Application"))
+}
+
+func TestUpdateAndSaveRoundTrip(t *testing.T) {
+	path := tempCheckpointPath(t)
+
+	c, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Update("Application", State{RecordNumber: 42})
+	c.Update("Microsoft-Windows-Sysmon/Operational", State{RecordNumber: 7})
+
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-open to verify the state was actually persisted to disk.
+	c2, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, State{RecordNumber: 42}, c2.State("Application"))
+	assert.Equal(t, State{RecordNumber: 7}, c2.State("Microsoft-Windows-Sysmon/Operational"))
+}
+
+func TestSaveIsNoOpWithoutChanges(t *testing.T) {
+	path := tempCheckpointPath(t)
+
+	c, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Save never wrote the file since nothing was ever Update()d.
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "expected no checkpoint file to be written")
+}
+
+func TestSaveIsAtomic(t *testing.T) {
+	path := tempCheckpointPath(t)
+
+	c, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Update("Application", State{RecordNumber: 1})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// No stray temp files should be left behind in the checkpoint directory.
+	entries, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 1)
+	assert.Equal(t, DefaultFileName, entries[0].Name())
+}
@@ -0,0 +1,30 @@
+package checkpoint
+
+import (
+	"time"
+
+	"github.com/elastic/libbeat/logp"
+)
+
+// Run periodically calls Save on the given interval until done is closed,
+// at which point it calls Save one final time to flush any state recorded
+// since the last tick before returning. It is intended to be run in its
+// own goroutine for the lifetime of the beat.
+func (c *Checkpoint) Run(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Save(); err != nil {
+				logp.Err("Failed to persist checkpoint state: %v", err)
+			}
+		case <-done:
+			if err := c.Save(); err != nil {
+				logp.Err("Failed to persist checkpoint state during shutdown: %v", err)
+			}
+			return
+		}
+	}
+}
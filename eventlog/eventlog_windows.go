@@ -0,0 +1,311 @@
+// +build windows
+
+package eventlog
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/elastic/libbeat/logp"
+	"github.com/elastic/winlogbeat/eventlog/filter"
+)
+
+// noMessageFile is the message format used when no EventMessageFile could
+// be found (or none of the files listed could resolve the event ID). It
+// mirrors the format Windows itself falls back to.
+const noMessageFile = "The description for Event ID %d from source %s " +
+	"cannot be found. Either the component that raises this event is not " +
+	"installed on your local computer or the installation is corrupted. " +
+	"You can install or repair the component on the local computer.\r\n" +
+	"\r\n" +
+	"If the event originated on another computer, the display " +
+	"information had to be saved with the event.\r\n" +
+	"\r\n" +
+	"The following information was included with the event: \r\n%s"
+
+// Handle is a Windows event log handle as returned by OpenEventLog.
+type Handle syscall.Handle
+
+// EventLoggingAPI reads events using the legacy EventLogging API
+// (advapi32.dll: OpenEventLog, ReadEventLog, ...). It only supports the
+// classic channels (Application, Security, System, and custom sources
+// registered the old way) and has no server-side filtering, but it works
+// on every supported version of Windows.
+type EventLoggingAPI struct {
+	name   string
+	filter *filter.Filter
+	handle Handle
+
+	// lastRecordNumber is the record number of the last record returned
+	// by Read. It is used to detect rollover of the record number space.
+	lastRecordNumber uint32
+
+	// needSeek is true for the one read that must use EVENTLOG_SEEK_READ
+	// to resume at lastRecordNumber+1. ReadEventLog only honors the
+	// requested record number on the first read of a freshly opened
+	// handle; every later read on that handle continues sequentially
+	// regardless of what is passed, so this is cleared after the first
+	// read (see readEventLog).
+	needSeek bool
+}
+
+// NewEventLoggingAPI creates a new EventLoggingAPI for the given source or
+// provider name (e.g. "Application", "Security", or a custom source).
+func NewEventLoggingAPI(name string) *EventLoggingAPI {
+	return NewEventLoggingAPIWithFilter(name, nil)
+}
+
+// NewEventLoggingAPIWithFilter creates a new EventLoggingAPI that only
+// returns records matching f. A nil filter matches everything.
+//
+// The EventLoggingAPI has no server-side filtering, so f is applied
+// in-process to every record returned by ReadEventLog.
+func NewEventLoggingAPIWithFilter(name string, f *filter.Filter) *EventLoggingAPI {
+	return &EventLoggingAPI{name: name, filter: f}
+}
+
+// Name returns the source/provider name being read.
+func (api *EventLoggingAPI) Name() string {
+	return api.name
+}
+
+// Open opens the event log for reading. recordNumber is the last record
+// number that was successfully processed; reading resumes after it. A
+// recordNumber of 0 means start from the oldest record, which is also
+// where a freshly opened handle starts by default, so no seek is needed
+// in that case.
+func (api *EventLoggingAPI) Open(recordNumber uint64) error {
+	handle, err := openEventLog(api.name)
+	if err != nil {
+		return err
+	}
+	api.handle = handle
+	api.lastRecordNumber = uint32(recordNumber)
+	api.needSeek = recordNumber > 0
+	return nil
+}
+
+// Close closes the event log handle.
+func (api *EventLoggingAPI) Close() error {
+	return closeEventLog(api.handle)
+}
+
+// Read reads all records that have been logged since the last call to
+// Read (or since Open, for the first call).
+func (api *EventLoggingAPI) Read() ([]Record, error) {
+	oldest, numRecords, err := api.recordRange()
+	if err != nil {
+		return nil, err
+	}
+
+	// Detect record number rollover (e.g. the log was cleared, or the
+	// record number space wrapped). When this happens there is no way to
+	// resume from lastRecordNumber so fall back to the oldest record.
+	if api.lastRecordNumber != 0 && oldest+numRecords < api.lastRecordNumber {
+		logp.Info("eventlog[%s] record number rollover detected, "+
+			"resuming from oldest available record %d", api.name, oldest)
+		api.lastRecordNumber = oldest - 1
+		api.needSeek = false
+	}
+
+	raw, err := readEventLog(api.handle, api.lastRecordNumber+1, api.needSeek)
+	api.needSeek = false
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(raw))
+	for _, r := range raw {
+		api.lastRecordNumber = r.RecordNumber32()
+
+		if api.filter != nil && !api.filter.Matches(eventTypeToLevel(r.EventType), r.EventID, r.ProviderName) {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// eventTypeToLevel maps a legacy EventLogging EventType bit to the same
+// Level numbering used by the wevtapi <System><Level> element, so that a
+// single filter.Config works against both backends.
+func eventTypeToLevel(eventType uint16) uint8 {
+	const (
+		eventTypeError       = 0x0001
+		eventTypeWarning     = 0x0002
+		eventTypeInformation = 0x0004
+		eventTypeAuditSuccess = 0x0008
+		eventTypeAuditFailure = 0x0010
+	)
+
+	switch eventType {
+	case eventTypeError:
+		return 2 // Error
+	case eventTypeWarning:
+		return 3 // Warning
+	case eventTypeAuditFailure:
+		return 2 // Error
+	case eventTypeAuditSuccess:
+		return 4 // Information
+	default:
+		return 4 // Information
+	}
+}
+
+// recordRange returns the record number of the oldest available record and
+// the number of records currently in the log.
+func (api *EventLoggingAPI) recordRange() (oldest, numRecords uint32, err error) {
+	oldest, err = getOldestEventLogRecord(api.handle)
+	if err != nil {
+		return 0, 0, err
+	}
+	numRecords, err = getNumberOfEventLogRecords(api.handle)
+	if err != nil {
+		return 0, 0, err
+	}
+	return oldest, numRecords, nil
+}
+
+// RecordNumber32 returns the record number truncated to uint32, which is
+// how the legacy EventLogging API represents it.
+func (r Record) RecordNumber32() uint32 {
+	return uint32(r.RecordNumber)
+}
+
+// clearEventLog clears the event log. If backupPath is non-empty, the
+// existing records are first backed up to that file.
+func clearEventLog(handle Handle, backupPath string) error {
+	var backupPathPtr *uint16
+	if backupPath != "" {
+		ptr, err := syscall.UTF16PtrFromString(backupPath)
+		if err != nil {
+			return err
+		}
+		backupPathPtr = ptr
+	}
+
+	r1, _, e1 := procClearEventLog.Call(
+		uintptr(handle),
+		uintptr(unsafe.Pointer(backupPathPtr)),
+	)
+	if r1 == 0 {
+		return fmt.Errorf("ClearEventLog failed: %w", e1)
+	}
+	return nil
+}
+
+// getNumberOfEventLogRecords returns the number of records currently
+// stored in the event log identified by handle.
+func getNumberOfEventLogRecords(handle Handle) (uint32, error) {
+	var count uint32
+	r1, _, e1 := procGetNumberOfEventLogRecords.Call(
+		uintptr(handle),
+		uintptr(unsafe.Pointer(&count)),
+	)
+	if r1 == 0 {
+		return 0, fmt.Errorf("GetNumberOfEventLogRecords failed: %w", e1)
+	}
+	return count, nil
+}
+
+// getOldestEventLogRecord returns the record number of the oldest record
+// still present in the event log identified by handle.
+func getOldestEventLogRecord(handle Handle) (uint32, error) {
+	var oldest uint32
+	r1, _, e1 := procGetOldestEventLogRecord.Call(
+		uintptr(handle),
+		uintptr(unsafe.Pointer(&oldest)),
+	)
+	if r1 == 0 {
+		return 0, fmt.Errorf("GetOldestEventLogRecord failed: %w", e1)
+	}
+	return oldest, nil
+}
+
+// openEventLog opens the event log or source named name on the local
+// computer.
+func openEventLog(name string) (Handle, error) {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+
+	r1, _, e1 := procOpenEventLogW.Call(
+		0, // lpUNCServerName: nil for the local computer.
+		uintptr(unsafe.Pointer(namePtr)),
+	)
+	if r1 == 0 {
+		return 0, fmt.Errorf("OpenEventLog failed for source '%v': %w", name, e1)
+	}
+	return Handle(r1), nil
+}
+
+// closeEventLog closes an event log handle opened with openEventLog.
+func closeEventLog(handle Handle) error {
+	r1, _, e1 := procCloseEventLog.Call(uintptr(handle))
+	if r1 == 0 {
+		return fmt.Errorf("CloseEventLog failed: %w", e1)
+	}
+	return nil
+}
+
+// readEventLog reads records starting at the given record number until no
+// more records are available. seek must be true to resume at recordNumber
+// on a freshly opened handle: ReadEventLog's dwRecordOffset argument is
+// ignored unless EVENTLOG_SEEK_READ is set, and EVENTLOG_SEQUENTIAL_READ
+// alone always starts from the oldest retained record regardless of what
+// is passed. seek only applies to the first call this function makes;
+// every later call in the loop switches to EVENTLOG_SEQUENTIAL_READ,
+// since re-seeking to a record the handle has already passed fails.
+func readEventLog(handle Handle, recordNumber uint32, seek bool) ([]Record, error) {
+	const (
+		eventlogSequentialRead = 0x0001
+		eventlogSeekRead       = 0x0002
+		eventlogForwardsRead   = 0x0004
+		bufferSize             = 1 << 16
+	)
+
+	flags := uint32(eventlogForwardsRead)
+	if seek {
+		flags |= eventlogSeekRead
+	} else {
+		flags |= eventlogSequentialRead
+	}
+
+	buf := make([]byte, bufferSize)
+	var records []Record
+
+	for {
+		var bytesRead, minBytesNeeded uint32
+		r1, _, e1 := procReadEventLogW.Call(
+			uintptr(handle),
+			uintptr(flags),
+			uintptr(recordNumber),
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(len(buf)),
+			uintptr(unsafe.Pointer(&bytesRead)),
+			uintptr(unsafe.Pointer(&minBytesNeeded)),
+		)
+		if r1 == 0 {
+			const errorHandleEOF = 38
+			if e1 == syscall.Errno(errorHandleEOF) {
+				break
+			}
+			return nil, fmt.Errorf("ReadEventLog failed: %w", e1)
+		}
+
+		parsed, err := parseEventLogRecords(buf[:bytesRead])
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, parsed...)
+		if len(parsed) == 0 {
+			break
+		}
+		recordNumber = parsed[len(parsed)-1].RecordNumber32() + 1
+		flags = uint32(eventlogSequentialRead | eventlogForwardsRead)
+	}
+
+	return records, nil
+}
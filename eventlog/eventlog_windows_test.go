@@ -0,0 +1,119 @@
+// +build windows
+
+package eventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildRawRecord hand-builds a single EVENTLOGRECORD buffer, matching the
+// layout parseEventLogRecords expects: the fixed eventLogRecord header,
+// followed by the NUL-terminated SourceName and Computer strings, then
+// the NUL-separated insert strings.
+//
+// This lets the parsing and message-assembly logic be exercised directly
+// with literal byte buffers, without a live event log or a real .evtx
+// file - the record shapes below capture the "multi-parameter message"
+// and "unknown EventID with insert strings" cases called out when the
+// golden-file harness in eventlog/evtxtest was added.
+func buildRawRecord(t *testing.T, sourceName, computerName string, eventID uint32, eventType uint16, inserts []string) []byte {
+	t.Helper()
+
+	headerSize := binary.Size(eventLogRecord{})
+	var body bytes.Buffer
+
+	writeUTF16Nul(t, &body, sourceName)
+	writeUTF16Nul(t, &body, computerName)
+
+	stringOffset := uint32(headerSize + body.Len())
+	for _, s := range inserts {
+		writeUTF16Nul(t, &body, s)
+	}
+
+	total := headerSize + body.Len()
+	header := eventLogRecord{
+		Length:       uint32(total),
+		RecordNumber: 1,
+		EventID:      eventID,
+		EventType:    eventType,
+		NumStrings:   uint16(len(inserts)),
+		StringOffset: stringOffset,
+	}
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, header); err != nil {
+		t.Fatal(err)
+	}
+	buf.Write(body.Bytes())
+
+	return buf.Bytes()
+}
+
+func writeUTF16Nul(t *testing.T, buf *bytes.Buffer, s string) {
+	t.Helper()
+	u16, err := syscall.UTF16FromString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range u16 { // UTF16FromString already appends a trailing NUL.
+		if err := binary.Write(buf, binary.LittleEndian, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestParseEventLogRecordsMultiParameterMessage verifies that a record
+// with more than one insert string round-trips through
+// parseEventLogRecords with its insert strings intact. Since the test
+// source name is never actually registered, EventMessageFile resolution
+// fails and the records fall back to noMessageFile - exercising that
+// fallback's insert-string formatting, covered below.
+func TestParseEventLogRecordsMultiParameterMessage(t *testing.T) {
+	const sourceName = "Winlogbeat Render Test Source"
+	const computerName = "TESTHOST"
+	const eventID uint32 = 1073748860 // Qualifiers=16384, EventID=7036.
+	inserts := []string{"Windows Update", "running"}
+
+	raw := buildRawRecord(t, sourceName, computerName, eventID, 4 /* Info */, inserts)
+
+	records, err := parseEventLogRecords(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	r := records[0]
+	assert.Equal(t, eventID&^0xFFFF0000, r.EventID)
+	assert.Equal(t, sourceName, r.ProviderName)
+	assert.Equal(t, computerName, r.ComputerName)
+	assert.Equal(t, fmt.Sprintf(noMessageFile, eventID&^0xFFFF0000, sourceName, "Windows Update, running"), r.Message)
+}
+
+// TestParseEventLogRecordsUnknownEventID covers a record with a single
+// insert string and no registered EventMessageFile, i.e. the "unknown
+// EventID" fallback.
+func TestParseEventLogRecordsUnknownEventID(t *testing.T) {
+	const sourceName = "Winlogbeat Render Test Source"
+	const eventID uint32 = 1000
+	const msg = "Test Message"
+
+	raw := buildRawRecord(t, sourceName, "TESTHOST", eventID, 1 /* Error */, []string{msg})
+
+	records, err := parseEventLogRecords(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	assert.Equal(t, fmt.Sprintf(noMessageFile, eventID, sourceName, msg), records[0].Message)
+}
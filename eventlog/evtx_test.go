@@ -0,0 +1,17 @@
+// +build windows
+
+package eventlog_test
+
+import (
+	"testing"
+
+	"github.com/elastic/winlogbeat/eventlog/evtxtest"
+)
+
+// TestEvtxGoldenFiles renders every fixture under testdata/ and compares
+// it to its golden XML. See eventlog/testdata/README.md for how to add
+// fixtures covering multi-parameter messages, unknown EventIDs, and
+// modern channels.
+func TestEvtxGoldenFiles(t *testing.T) {
+	evtxtest.RunGoldenFileTests(t, "testdata")
+}
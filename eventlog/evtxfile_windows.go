@@ -0,0 +1,102 @@
+// +build windows
+
+package eventlog
+
+import "fmt"
+
+// EvtxFileReader reads events from a saved .evtx file using EvtQuery with
+// the EvtQueryFilePath flag. It implements the EventLog interface so that
+// archived logs can be replayed through the same code path as a live
+// channel, which also makes the render logic testable without a live
+// Windows event source (see eventlog/evtxtest).
+type EvtxFileReader struct {
+	path string
+	inner *WinEventLog
+}
+
+// NewEvtxFileReader creates an EventLog that reads the .evtx file at path.
+func NewEvtxFileReader(path string) (*EvtxFileReader, error) {
+	return NewEvtxFileReaderWithQuery(path, "*")
+}
+
+// NewEvtxFileReaderWithQuery creates an EvtxFileReader for the .evtx file
+// at path, scoped to the events matched by the given XPath query.
+func NewEvtxFileReaderWithQuery(path, query string) (*EvtxFileReader, error) {
+	if query == "" {
+		query = "*"
+	}
+	return &EvtxFileReader{
+		path:  path,
+		inner: &WinEventLog{channel: path, query: query},
+	}, nil
+}
+
+// Name returns the path of the .evtx file being read.
+func (r *EvtxFileReader) Name() string {
+	return r.path
+}
+
+// Open opens the .evtx file, resuming after recordNumber if it is
+// non-zero. Subscriptions are not supported against files, so this always
+// uses a one-shot EvtQuery.
+func (r *EvtxFileReader) Open(recordNumber uint64) error {
+	query := r.inner.query
+	if recordNumber > 0 {
+		query = withRecordNumberPredicate(query, recordNumber)
+	}
+
+	handle, err := evtQuery(r.path, query, evtQueryFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open evtx file '%v': %w", r.path, err)
+	}
+	r.inner.handle = handle
+	r.inner.subscription = false
+	return nil
+}
+
+// Read renders and returns every event currently available.
+func (r *EvtxFileReader) Read() ([]Record, error) {
+	return r.inner.Read()
+}
+
+// Close closes the file handle.
+func (r *EvtxFileReader) Close() error {
+	return r.inner.Close()
+}
+
+// RenderXML reads every event in the .evtx file at path and returns its
+// rendered <Event> XML, in order, without formatting a message for it.
+// EvtFormatMessage depends on the logging provider's message resources
+// being installed locally, which is normally not true when replaying an
+// .evtx file captured on a different machine, so the golden-file test
+// harness in eventlog/evtxtest compares this raw XML instead of the
+// formatted Record.Message.
+func RenderXML(path string) ([]string, error) {
+	handle, err := evtQuery(path, "*", evtQueryFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open evtx file '%v': %w", path, err)
+	}
+	defer evtClose(handle)
+
+	var renderBuf []uint16
+	var docs []string
+	for {
+		eventHandles, err := evtNext(handle, 512)
+		if err == errNoMoreItems {
+			break
+		}
+		if err != nil {
+			return docs, fmt.Errorf("EvtNext failed for '%v': %w", path, err)
+		}
+
+		for _, eh := range eventHandles {
+			xmlStr, err := evtRenderXML(eventHandle(eh), &renderBuf)
+			evtClose(eventHandle(eh))
+			if err != nil {
+				return docs, fmt.Errorf("EvtRender failed for '%v': %w", path, err)
+			}
+			docs = append(docs, xmlStr)
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,69 @@
+// +build windows
+
+// Package evtxtest provides a golden-file test harness for the eventlog
+// render code. It pairs each testdata/<name>.evtx fixture with a
+// testdata/<name>.evtx.golden.xml file holding the expected rendered XML
+// for every event in the fixture, so changes to the render path can be
+// checked against real event shapes without a live Windows event source.
+package evtxtest
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elastic/winlogbeat/eventlog"
+	"github.com/stretchr/testify/assert"
+)
+
+// update regenerates the .golden.xml files instead of comparing against
+// them. Run with `go test ./eventlog -update` after adding or
+// changing a fixture.
+var update = flag.Bool("update", false, "update .golden.xml files")
+
+// RunGoldenFileTests renders every testdata/*.evtx file under dir and
+// compares the result to its paired <name>.evtx.golden.xml file, as a
+// subtest per fixture. Fixtures are captured on a real Windows host and
+// checked in separately (see testdata/README.md); in short mode (go test
+// -short) a missing testdata/ is skipped, but a run without -short fails
+// loudly instead of silently passing, so an empty testdata/
+// can't masquerade as render-code coverage.
+func RunGoldenFileTests(t *testing.T, dir string) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.evtx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) == 0 {
+		if testing.Short() {
+			t.Skip("no .evtx fixtures found in " + dir)
+		}
+		t.Fatalf("no .evtx fixtures found in %v; see testdata/README.md", dir)
+	}
+
+	for _, evtxPath := range matches {
+		evtxPath := evtxPath
+		t.Run(filepath.Base(evtxPath), func(t *testing.T) {
+			docs, err := eventlog.RenderXML(evtxPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := strings.Join(docs, "\n")
+
+			goldenPath := evtxPath + ".golden.xml"
+			if *update {
+				if err := ioutil.WriteFile(goldenPath, []byte(actual), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := ioutil.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("missing golden file %v (run with -update to create it): %v", goldenPath, err)
+			}
+			assert.Equal(t, string(expected), actual)
+		})
+	}
+}
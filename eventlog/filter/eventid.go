@@ -0,0 +1,134 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// idRange is an inclusive range of event IDs. A single ID is represented
+// as min == max.
+type idRange struct {
+	min, max uint32
+}
+
+func (r idRange) contains(id uint32) bool {
+	return id >= r.min && id <= r.max
+}
+
+// idFilter is a compiled event_id filter: an event ID matches if it falls
+// in one of includes (or includes is empty) and does not fall in any of
+// excludes.
+type idFilter struct {
+	includes []idRange
+	excludes []idRange
+}
+
+// parseEventIDs parses a comma-separated list of event IDs and ranges,
+// where a leading '-' excludes the ID or range instead of including it
+// (e.g. "4624,4634,4700-4800,-4688").
+func parseEventIDs(s string) (*idFilter, error) {
+	f := &idFilter{}
+
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		exclude := strings.HasPrefix(token, "-")
+		if exclude {
+			token = token[1:]
+		}
+
+		r, err := parseIDRange(token)
+		if err != nil {
+			return nil, err
+		}
+
+		if exclude {
+			f.excludes = append(f.excludes, r)
+		} else {
+			f.includes = append(f.includes, r)
+		}
+	}
+
+	return f, nil
+}
+
+func parseIDRange(token string) (idRange, error) {
+	if lo, hi, ok := splitRange(token); ok {
+		min, err := strconv.ParseUint(lo, 10, 32)
+		if err != nil {
+			return idRange{}, fmt.Errorf("invalid event_id range '%v': %w", token, err)
+		}
+		max, err := strconv.ParseUint(hi, 10, 32)
+		if err != nil {
+			return idRange{}, fmt.Errorf("invalid event_id range '%v': %w", token, err)
+		}
+		return idRange{min: uint32(min), max: uint32(max)}, nil
+	}
+
+	id, err := strconv.ParseUint(token, 10, 32)
+	if err != nil {
+		return idRange{}, fmt.Errorf("invalid event_id '%v': %w", token, err)
+	}
+	return idRange{min: uint32(id), max: uint32(id)}, nil
+}
+
+// match reports whether id passes the filter.
+func (f *idFilter) match(id uint32) bool {
+	for _, r := range f.excludes {
+		if r.contains(id) {
+			return false
+		}
+	}
+
+	if len(f.includes) == 0 {
+		return true
+	}
+
+	for _, r := range f.includes {
+		if r.contains(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// xpath renders the event ID filter as an XPath 1.0 predicate fragment,
+// e.g. "((EventID=4624 or EventID=4634 or (EventID>=4700 and
+// EventID<=4800)) and not(EventID=4688))".
+func (f *idFilter) xpath() string {
+	predicate := rangesToXPath(f.includes, "EventID")
+	if predicate == "" {
+		predicate = "EventID"
+	}
+
+	for _, r := range f.excludes {
+		predicate = fmt.Sprintf("(%s and not(%s))", predicate, rangeToXPath(r, "EventID"))
+	}
+	return predicate
+}
+
+func rangesToXPath(ranges []idRange, field string) string {
+	if len(ranges) == 0 {
+		return ""
+	}
+
+	predicate := ""
+	for _, r := range ranges {
+		if predicate != "" {
+			predicate += " or "
+		}
+		predicate += rangeToXPath(r, field)
+	}
+	return "(" + predicate + ")"
+}
+
+func rangeToXPath(r idRange, field string) string {
+	if r.min == r.max {
+		return fmt.Sprintf("%s=%d", field, r.min)
+	}
+	return fmt.Sprintf("(%s>=%d and %s<=%d)", field, r.min, field, r.max)
+}
@@ -0,0 +1,133 @@
+// Package filter parses and compiles the per-channel event filtering
+// configuration (level, event_id, provider, and a raw xpath escape hatch)
+// shared by both EventLog backends.
+//
+// The EventLoggingAPI backend has no server-side filtering, so it
+// compiles a Config into a Filter and calls Matches on each record it
+// reads. The WinEventLog backend instead calls XPath to obtain an XPath
+// 1.0 predicate that it hands to EvtQuery/EvtSubscribe so that filtering
+// happens inside the Windows Event Log service.
+package filter
+
+import "fmt"
+
+// Config is the user-facing, per-channel filter configuration.
+//
+//	level:    "critical,error,warning" or "1-3" (comma list of names and/or
+//	          numbers, ranges allowed)
+//	event_id: "4624,4634,4700-4800,-4688" (comma list of numbers and/or
+//	          ranges; a leading '-' excludes)
+//	provider: list of provider/source names
+//	xpath:    a raw XPath 1.0 predicate passed straight through to
+//	          EvtQuery, bypassing level/event_id/provider entirely
+type Config struct {
+	Level    string   `config:"level"`
+	EventID  string   `config:"event_id"`
+	Provider []string `config:"provider"`
+	XPath    string   `config:"xpath"`
+}
+
+// Filter is a compiled Config.
+type Filter struct {
+	levels    levelSet
+	eventIDs  *idFilter
+	providers map[string]bool
+	xpath     string
+}
+
+// New compiles c into a Filter. An empty Config produces a Filter that
+// matches everything.
+func New(c Config) (*Filter, error) {
+	if c.XPath != "" {
+		return &Filter{xpath: c.XPath}, nil
+	}
+
+	f := &Filter{}
+
+	if c.Level != "" {
+		levels, err := parseLevels(c.Level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level filter '%v': %w", c.Level, err)
+		}
+		f.levels = levels
+	}
+
+	if c.EventID != "" {
+		ids, err := parseEventIDs(c.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event_id filter '%v': %w", c.EventID, err)
+		}
+		f.eventIDs = ids
+	}
+
+	if len(c.Provider) > 0 {
+		f.providers = make(map[string]bool, len(c.Provider))
+		for _, p := range c.Provider {
+			f.providers[p] = true
+		}
+	}
+
+	f.xpath = f.compileXPath()
+	return f, nil
+}
+
+// Matches reports whether a record with the given level, event ID, and
+// provider name passes the filter. It is used by the EventLoggingAPI
+// backend, which must filter in-process after reading each record.
+func (f *Filter) Matches(level uint8, eventID uint32, provider string) bool {
+	if f.levels != nil && !f.levels[level] {
+		return false
+	}
+	if f.eventIDs != nil && !f.eventIDs.match(eventID) {
+		return false
+	}
+	if f.providers != nil && !f.providers[provider] {
+		return false
+	}
+	return true
+}
+
+// XPath returns the XPath 1.0 predicate equivalent to the filter, for use
+// with EvtQuery/EvtSubscribe. If the Config set XPath directly, that raw
+// value is returned unmodified.
+func (f *Filter) XPath() string {
+	return f.xpath
+}
+
+// compileXPath builds the "*[System[...]]" predicate from the level,
+// event_id, and provider filters. Returns "*" (match everything) if none
+// of them were set.
+func (f *Filter) compileXPath() string {
+	var clauses []string
+
+	if f.levels != nil {
+		clauses = append(clauses, f.levels.xpath())
+	}
+	if f.eventIDs != nil {
+		clauses = append(clauses, f.eventIDs.xpath())
+	}
+	if f.providers != nil {
+		clauses = append(clauses, providerXPath(f.providers))
+	}
+
+	if len(clauses) == 0 {
+		return "*"
+	}
+
+	predicate := clauses[0]
+	for _, c := range clauses[1:] {
+		predicate += " and " + c
+	}
+	return fmt.Sprintf("*[System[%s]]", predicate)
+}
+
+func providerXPath(providers map[string]bool) string {
+	predicate := ""
+	for name := range providers {
+		if predicate != "" {
+			predicate += " or "
+		}
+		predicate += fmt.Sprintf("Provider[@Name='%s']", name)
+	}
+	return "(" + predicate + ")"
+}
@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterLevel(t *testing.T) {
+	f, err := New(Config{Level: "critical,error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, f.Matches(1, 1, "any"), "critical should match")
+	assert.True(t, f.Matches(2, 1, "any"), "error should match")
+	assert.False(t, f.Matches(3, 1, "any"), "warning should not match")
+}
+
+func TestFilterLevelRange(t *testing.T) {
+	f, err := New(Config{Level: "1-3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, f.Matches(1, 1, "any"))
+	assert.True(t, f.Matches(3, 1, "any"))
+	assert.False(t, f.Matches(4, 1, "any"))
+}
+
+func TestFilterEventID(t *testing.T) {
+	f, err := New(Config{EventID: "4624,4634,4700-4800,-4688"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, f.Matches(0, 4624, "any"))
+	assert.True(t, f.Matches(0, 4634, "any"))
+	assert.True(t, f.Matches(0, 4750, "any"), "4750 is within the 4700-4800 range")
+	assert.False(t, f.Matches(0, 4688, "any"), "4688 is explicitly excluded")
+	assert.False(t, f.Matches(0, 9999, "any"), "9999 was never included")
+}
+
+func TestFilterEventIDExcludeOnly(t *testing.T) {
+	f, err := New(Config{EventID: "-4688"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, f.Matches(0, 4688, "any"))
+	assert.True(t, f.Matches(0, 1, "any"), "everything but the excluded ID should match")
+}
+
+func TestFilterProvider(t *testing.T) {
+	f, err := New(Config{Provider: []string{"Microsoft-Windows-Security-Auditing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, f.Matches(0, 0, "Microsoft-Windows-Security-Auditing"))
+	assert.False(t, f.Matches(0, 0, "Other-Provider"))
+}
+
+func TestFilterXPathEscapeHatch(t *testing.T) {
+	f, err := New(Config{
+		XPath: "*[System[EventID=4624]]",
+		Level: "critical", // ignored when XPath is set.
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "*[System[EventID=4624]]", f.XPath())
+}
+
+func TestFilterEmptyConfigMatchesEverything(t *testing.T) {
+	f, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, f.Matches(5, 12345, "anything"))
+	assert.Equal(t, "*", f.XPath())
+}
+
+func TestFilterCompiledXPath(t *testing.T) {
+	f, err := New(Config{Level: "critical,error", EventID: "4624,-4688"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xpath := f.XPath()
+	assert.Contains(t, xpath, "Level=1")
+	assert.Contains(t, xpath, "Level=2")
+	assert.Contains(t, xpath, "EventID=4624")
+	assert.Contains(t, xpath, "not(EventID=4688)")
+}
+
+func TestFilterInvalidLevel(t *testing.T) {
+	_, err := New(Config{Level: "not-a-level"})
+	assert.Error(t, err)
+}
+
+func TestFilterInvalidEventID(t *testing.T) {
+	_, err := New(Config{EventID: "not-an-id"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// levelSet is the set of raw Level values (as reported in an event's
+// <System> element) that a level filter accepts.
+type levelSet map[uint8]bool
+
+// levelNames maps the user-facing level names to the raw numeric Level
+// values of the wevtapi <System><Level> element. The EventLoggingAPI
+// backend maps each record's EventType onto the same numbering before
+// filtering.
+var levelNames = map[string]uint8{
+	"logalways":   0,
+	"critical":    1,
+	"error":       2,
+	"warning":     3,
+	"information": 4,
+	"info":        4,
+	"verbose":     5,
+}
+
+// parseLevels parses a comma-separated list of level names and/or numbers
+// and numeric ranges (e.g. "critical,error,warning" or "1-3") into a
+// levelSet.
+func parseLevels(s string) (levelSet, error) {
+	set := levelSet{}
+
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		if lo, hi, ok := splitRange(token); ok {
+			min, err := strconv.ParseUint(lo, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid level range '%v': %w", token, err)
+			}
+			max, err := strconv.ParseUint(hi, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid level range '%v': %w", token, err)
+			}
+			for v := min; v <= max; v++ {
+				set[uint8(v)] = true
+			}
+			continue
+		}
+
+		if level, ok := levelNames[strings.ToLower(token)]; ok {
+			set[level] = true
+			continue
+		}
+
+		level, err := strconv.ParseUint(token, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("unknown level '%v'", token)
+		}
+		set[uint8(level)] = true
+	}
+
+	return set, nil
+}
+
+// xpath renders the level set as an XPath 1.0 predicate fragment, e.g.
+// "(Level=2 or Level=3)".
+func (s levelSet) xpath() string {
+	predicate := ""
+	for level := range s {
+		if predicate != "" {
+			predicate += " or "
+		}
+		predicate += fmt.Sprintf("Level=%d", level)
+	}
+	return "(" + predicate + ")"
+}
+
+// splitRange splits "lo-hi" into its two components. ok is false if token
+// does not look like a range.
+func splitRange(token string) (lo, hi string, ok bool) {
+	i := strings.IndexByte(token, '-')
+	if i <= 0 || i == len(token)-1 {
+		return "", "", false
+	}
+	return token[:i], token[i+1:], true
+}
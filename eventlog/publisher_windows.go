@@ -0,0 +1,105 @@
+// +build windows
+
+package eventlog
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// PublisherMetadataHandle is an EVT_HANDLE returned by
+// EvtOpenPublisherMetadata. It is used to resolve the display strings for
+// a provider's raw Level/Opcode/Task/Keywords values without needing a
+// live event handle (unlike formatting a full event message).
+type PublisherMetadataHandle eventHandle
+
+var procEvtOpenPublisherMetadata = modwevtapi.NewProc("EvtOpenPublisherMetadata")
+
+// OpenPublisherMetadata opens the publisher metadata for providerName.
+// The returned handle should be closed with Close once no longer needed;
+// callers that resolve values repeatedly for the same provider (such as
+// winevent.EnrichRawValuesWithNames) are expected to keep it open and
+// reuse it rather than reopening it per event.
+func OpenPublisherMetadata(providerName string) (PublisherMetadataHandle, error) {
+	namePtr, err := syscall.UTF16PtrFromString(providerName)
+	if err != nil {
+		return 0, err
+	}
+
+	r1, _, e1 := procEvtOpenPublisherMetadata.Call(
+		0, // session: nil for the local computer.
+		uintptr(unsafe.Pointer(namePtr)),
+		0, // logFilePath: nil, resolve from the registered provider.
+		0, // locale: 0, use the current locale.
+		0,
+	)
+	if r1 == 0 {
+		return 0, fmt.Errorf("EvtOpenPublisherMetadata failed for provider '%v': %w", providerName, e1)
+	}
+	return PublisherMetadataHandle(r1), nil
+}
+
+// Close closes the publisher metadata handle.
+func (h PublisherMetadataHandle) Close() error {
+	return evtClose(eventHandle(h))
+}
+
+// FormatMessageFlag mirrors the subset of EVT_FORMAT_MESSAGE_FLAGS used to
+// resolve a single raw System value to its display string, as opposed to
+// rendering a full event message with EvtFormatMessageEvent.
+type FormatMessageFlag uint32
+
+// EVT_FORMAT_MESSAGE_FLAGS values needed to resolve System field names.
+const (
+	FormatMessageLevel   FormatMessageFlag = 2
+	FormatMessageTask    FormatMessageFlag = 3
+	FormatMessageOpcode  FormatMessageFlag = 4
+	FormatMessageKeyword FormatMessageFlag = 5
+)
+
+// evtVarTypeUInt64 is the EVT_VARIANT_TYPE tag for a UInt64 value.
+const evtVarTypeUInt64 = 10
+
+// evtVariant mirrors enough of the EVT_VARIANT union to pass a single
+// UInt64 value in EvtFormatMessage's Values array, which is what the
+// Level/Opcode/Task/Keyword flags expect in place of a live event handle.
+type evtVariant struct {
+	data    uint64
+	count   uint32
+	varType uint32
+}
+
+// FormatValue resolves the display string for a single raw value (a
+// Level, Opcode, or Task, or one bit of a Keywords mask) against the
+// given publisher's metadata.
+func FormatValue(h PublisherMetadataHandle, flag FormatMessageFlag, value uint64) (string, error) {
+	v := evtVariant{data: value, count: 1, varType: evtVarTypeUInt64}
+
+	bufSizeChars := uint32(128)
+	for {
+		buf := make([]uint16, bufSizeChars)
+		var bufferUsed uint32
+
+		r1, _, e1 := procEvtFormatMessage.Call(
+			uintptr(h),
+			0, // event: not required to resolve a System field value.
+			0, // messageID: unused outside of EvtFormatMessageId.
+			1, // valueCount
+			uintptr(unsafe.Pointer(&v)),
+			uintptr(flag),
+			uintptr(bufSizeChars), // BufferSize is in WCHARs.
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&bufferUsed)), // BufferUsed is also in WCHARs.
+		)
+		if r1 != 0 {
+			return syscall.UTF16ToString(buf[:bufferUsed]), nil
+		}
+
+		const errorInsufficientBuffer = syscall.Errno(122)
+		if e1 != errorInsufficientBuffer {
+			return "", fmt.Errorf("EvtFormatMessage failed: %w", e1)
+		}
+		bufSizeChars = bufferUsed
+	}
+}
@@ -0,0 +1,36 @@
+// +build windows
+
+package eventlog
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// readSemicolonSeparatedRegValue reads the string value named valueName
+// under HKEY_LOCAL_MACHINE\keyPath and splits it on ';', trimming
+// whitespace and dropping empty entries. Environment variable references
+// are left intact for the caller to expand
+// (e.g. "%SystemRoot%\\System32\\foo.dll").
+func readSemicolonSeparatedRegValue(keyPath, valueName string) ([]string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(valueName)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(value, ";")
+	files := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			files = append(files, p)
+		}
+	}
+	return files, nil
+}
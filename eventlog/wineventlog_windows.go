@@ -0,0 +1,276 @@
+// +build windows
+
+package eventlog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"syscall"
+
+	"github.com/elastic/libbeat/logp"
+)
+
+// eventHandle is an EVT_HANDLE as returned by the wevtapi.dll functions
+// (EvtQuery, EvtSubscribe, EvtNext, EvtCreateBookmark, ...).
+type eventHandle syscall.Handle
+
+// Execution identifies the process and thread that logged an event, taken
+// from the <Execution> element of the event's rendered <System> section.
+type Execution struct {
+	ProcessID uint32
+	ThreadID  uint32
+}
+
+// KeyValue is a single name/value pair taken from the EventData or
+// UserData section of an event rendered by the wevtapi backend.
+type KeyValue struct {
+	Name  string
+	Value string
+}
+
+// WinEventLog reads events using the modern Windows Event Log API
+// (wevtapi.dll: EvtQuery, EvtSubscribe, EvtNext, EvtRender, ...). Unlike
+// EventLoggingAPI it can read any channel (including the
+// Microsoft-Windows-*/Operational channels), supports XPath filtering,
+// and renders structured EventData/UserData.
+type WinEventLog struct {
+	channel string
+	query   string // XPath query passed to EvtQuery/EvtSubscribe.
+
+	handle       eventHandle // EvtQuery or EvtSubscribe result.
+	subscription bool        // true if handle was opened with EvtSubscribe.
+
+	// signalEvent is the Win32 event handle passed to EvtSubscribe. It is
+	// only set (and must only be closed) when subscription is true.
+	signalEvent syscall.Handle
+
+	renderBuf []uint16 // Reused buffer for EvtRender, sized in WCHARs.
+}
+
+// NewWinEventLog creates a WinEventLog for the given channel. Use
+// NewWinEventLogWithQuery to additionally scope the channel with an XPath
+// filter (see the eventlog/filter package).
+func NewWinEventLog(channel string) (*WinEventLog, error) {
+	return NewWinEventLogWithQuery(channel, "*")
+}
+
+// NewWinEventLogWithQuery creates a WinEventLog for channel, scoped to the
+// events matched by the given XPath query.
+func NewWinEventLogWithQuery(channel, query string) (*WinEventLog, error) {
+	if query == "" {
+		query = "*"
+	}
+	return &WinEventLog{channel: channel, query: query}, nil
+}
+
+// Name returns the channel name being read.
+func (l *WinEventLog) Name() string {
+	return l.channel
+}
+
+// Open opens the channel for reading, resuming after recordNumber if it is
+// non-zero. Open prefers push mode (EvtSubscribe) so that new events are
+// delivered as they are logged; callers that need classic poll-based
+// behavior can call Read in a loop regardless of the underlying mode.
+//
+// Resuming uses evtSubscribeStartAtOldestRecord together with the
+// EventRecordID predicate added by withRecordNumberPredicate, rather than
+// evtSubscribeToFutureEvents: the latter only delivers events logged
+// after the subscription is created and would silently drop anything
+// logged to the channel while Winlogbeat was stopped, defeating the
+// checkpoint/resume feature. A recordNumber of 0 also subscribes with
+// evtSubscribeStartAtOldestRecord, so the events already in the channel
+// are read from the beginning.
+func (l *WinEventLog) Open(recordNumber uint64) error {
+	query := l.query
+	subscribeFlags := uint32(evtSubscribeStartAtOldestRecord)
+	if recordNumber > 0 {
+		query = withRecordNumberPredicate(query, recordNumber)
+	}
+
+	signalEvent, err := createResetEvent()
+	if err != nil {
+		return fmt.Errorf("failed to create subscription signal event: %w", err)
+	}
+
+	handle, err := evtSubscribe(l.channel, query, signalEvent, subscribeFlags)
+	if err != nil {
+		// Some channels do not support subscriptions; fall back to a
+		// one-shot pull query.
+		logp.Debug("eventlog", "EvtSubscribe failed for channel %v, "+
+			"falling back to EvtQuery: %v", l.channel, err)
+
+		syscall.CloseHandle(signalEvent)
+		handle, err = evtQuery(l.channel, query, evtQueryChannelPath)
+		if err != nil {
+			return fmt.Errorf("failed to open channel '%v': %w", l.channel, err)
+		}
+		l.subscription = false
+	} else {
+		l.subscription = true
+		l.signalEvent = signalEvent
+	}
+
+	l.handle = handle
+	return nil
+}
+
+// Close closes the underlying EVT_HANDLE, along with the signal event
+// handle created for EvtSubscribe, if one was opened in push mode.
+func (l *WinEventLog) Close() error {
+	if l.subscription {
+		syscall.CloseHandle(l.signalEvent)
+	}
+	return evtClose(l.handle)
+}
+
+// Read reads and renders the events that are currently available without
+// blocking.
+func (l *WinEventLog) Read() ([]Record, error) {
+	var records []Record
+
+	for {
+		eventHandles, err := evtNext(l.handle, 512)
+		if err == errNoMoreItems {
+			break
+		}
+		if err != nil {
+			return records, fmt.Errorf("EvtNext failed for channel '%v': %w", l.channel, err)
+		}
+		if len(eventHandles) == 0 {
+			break
+		}
+
+		for _, eh := range eventHandles {
+			record, err := l.renderRecord(eh)
+			evtClose(eventHandle(eh))
+			if err != nil {
+				logp.Warn("eventlog[%v] failed to render event: %v", l.channel, err)
+				continue
+			}
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// renderRecord renders eh's XML fragment and the formatted message, then
+// parses the XML into a Record.
+func (l *WinEventLog) renderRecord(eh eventHandle) (Record, error) {
+	xmlStr, err := evtRenderXML(eh, &l.renderBuf)
+	if err != nil {
+		return Record{}, fmt.Errorf("EvtRender failed: %w", err)
+	}
+
+	record, err := parseEventXML([]byte(xmlStr))
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to parse rendered event XML: %w", err)
+	}
+
+	message, err := evtFormatMessage(eh, evtFormatMessageEvent)
+	if err != nil {
+		// Missing message resources are common (e.g. the provider's
+		// manifest is not installed locally); log the fragment instead of
+		// failing the whole record.
+		logp.Debug("eventlog", "EvtFormatMessage failed for event ID %d "+
+			"on channel '%v': %v", record.EventID, l.channel, err)
+	} else {
+		record.Message = message
+	}
+
+	return record, nil
+}
+
+// eventSystem mirrors the <System> element of the Windows Event Schema
+// that EvtRender produces for every event.
+type eventSystem struct {
+	Provider struct {
+		Name string `xml:"Name,attr"`
+		GUID string `xml:"Guid,attr"`
+	} `xml:"Provider"`
+	EventID       uint32 `xml:"EventID"`
+	Level         uint8  `xml:"Level"`
+	Task          uint16 `xml:"Task"`
+	// Opcode is a pointer because it is legitimately absent from the
+	// rendered XML for several events on newer Windows versions; a
+	// missing element must not be confused with an explicit Opcode
+	// of 0 (Info).
+	Opcode        *uint8 `xml:"Opcode"`
+	Keywords      string `xml:"Keywords"`
+	EventRecordID uint64 `xml:"EventRecordID"`
+	Correlation   struct {
+		ActivityID        string `xml:"ActivityID,attr"`
+		RelatedActivityID string `xml:"RelatedActivityID,attr"`
+	} `xml:"Correlation"`
+	Execution struct {
+		ProcessID uint32 `xml:"ProcessID,attr"`
+		ThreadID  uint32 `xml:"ThreadID,attr"`
+	} `xml:"Execution"`
+	Channel string `xml:"Channel"`
+	Computer string `xml:"Computer"`
+}
+
+// eventXML mirrors the top-level <Event> element rendered by EvtRender.
+type eventXML struct {
+	System    eventSystem `xml:"System"`
+	EventData struct {
+		Data []eventDatum `xml:"Data"`
+	} `xml:"EventData"`
+	UserData struct {
+		InnerXML []byte `xml:",innerxml"`
+	} `xml:"UserData"`
+}
+
+// eventDatum mirrors a single <Data Name="..."> element of <EventData>.
+type eventDatum struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// parseEventXML parses the XML fragment rendered by EvtRender into a
+// Record, extracting the <System> fields and the raw EventData/UserData
+// name-value pairs.
+func parseEventXML(data []byte) (Record, error) {
+	var e eventXML
+	if err := xml.Unmarshal(data, &e); err != nil {
+		return Record{}, err
+	}
+
+	eventData := make([]KeyValue, 0, len(e.EventData.Data))
+	for _, d := range e.EventData.Data {
+		eventData = append(eventData, KeyValue{Name: d.Name, Value: d.Value})
+	}
+
+	return Record{
+		RecordNumber:      e.System.EventRecordID,
+		EventID:           e.System.EventID,
+		ProviderName:      e.System.Provider.Name,
+		ProviderGUID:      e.System.Provider.GUID,
+		ComputerName:      e.System.Computer,
+		Channel:           e.System.Channel,
+		LevelRaw:          e.System.Level,
+		OpcodeRaw:         e.System.Opcode,
+		Task:              e.System.Task,
+		KeywordsRaw:       e.System.Keywords,
+		ActivityID:        e.System.Correlation.ActivityID,
+		RelatedActivityID: e.System.Correlation.RelatedActivityID,
+		Execution: Execution{
+			ProcessID: e.System.Execution.ProcessID,
+			ThreadID:  e.System.Execution.ThreadID,
+		},
+		EventData: eventData,
+		UserDataXML: string(e.UserData.InnerXML),
+	}, nil
+}
+
+// withRecordNumberPredicate appends an EventRecordID lower-bound predicate
+// to query so that resuming a pull-mode read skips records that were
+// already processed.
+func withRecordNumberPredicate(query string, recordNumber uint64) string {
+	predicate := fmt.Sprintf("*[System[(EventRecordID>%d)]]", recordNumber)
+	if query == "" || query == "*" {
+		return predicate
+	}
+	return fmt.Sprintf("(%s) and %s", query, predicate)
+}
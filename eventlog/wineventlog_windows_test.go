@@ -0,0 +1,71 @@
+// +build windows
+
+package eventlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// modernChannelEventXML is a literal stand-in for the XML EvtRender
+// produces for an event on a modern (non-Classic) channel such as
+// Microsoft-Windows-Sysmon/Operational. Its <System> element omits
+// <Opcode> entirely, as many such providers do - the case OpcodeRaw's
+// *uint8 type exists to distinguish from an explicit Opcode of 0 (Info).
+const modernChannelEventXML = `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System>
+    <Provider Name="Microsoft-Windows-Sysmon" Guid="{5770385f-c22a-43e0-bf4c-06f5698ffbd9}"/>
+    <EventID>1</EventID>
+    <Level>4</Level>
+    <Task>1</Task>
+    <Keywords>0x8000000000000000</Keywords>
+    <EventRecordID>4242</EventRecordID>
+    <Correlation/>
+    <Execution ProcessID="1234" ThreadID="5678"/>
+    <Channel>Microsoft-Windows-Sysmon/Operational</Channel>
+    <Computer>TESTHOST</Computer>
+  </System>
+  <EventData>
+    <Data Name="UtcTime">2026-07-26 00:00:00.000</Data>
+    <Data Name="Image">C:\Windows\System32\cmd.exe</Data>
+  </EventData>
+</Event>`
+
+func TestParseEventXMLModernChannelNoOpcode(t *testing.T) {
+	record, err := parseEventXML([]byte(modernChannelEventXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Microsoft-Windows-Sysmon/Operational", record.Channel)
+	assert.Equal(t, uint64(4242), record.RecordNumber)
+	assert.Nil(t, record.OpcodeRaw, "Opcode element is absent; OpcodeRaw must be nil, not a zero value")
+	assert.Equal(t, []KeyValue{
+		{Name: "UtcTime", Value: "2026-07-26 00:00:00.000"},
+		{Name: "Image", Value: `C:\Windows\System32\cmd.exe`},
+	}, record.EventData)
+}
+
+func TestParseEventXMLExplicitOpcodeZero(t *testing.T) {
+	const xmlWithOpcode = `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System>
+    <Provider Name="TestProvider"/>
+    <EventID>1</EventID>
+    <Level>4</Level>
+    <Opcode>0</Opcode>
+    <EventRecordID>1</EventRecordID>
+    <Channel>Application</Channel>
+    <Computer>TESTHOST</Computer>
+  </System>
+</Event>`
+
+	record, err := parseEventXML([]byte(xmlWithOpcode))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.NotNil(t, record.OpcodeRaw, "explicit <Opcode>0</Opcode> must not be parsed as absent") {
+		assert.Equal(t, uint8(0), *record.OpcodeRaw)
+	}
+}
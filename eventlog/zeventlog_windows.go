@@ -0,0 +1,260 @@
+// +build windows
+
+package eventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Windows API procedures used by the legacy EventLogging backend.
+var (
+	modadvapi32 = syscall.NewLazyDLL("advapi32.dll")
+
+	procOpenEventLogW             = modadvapi32.NewProc("OpenEventLogW")
+	procCloseEventLog             = modadvapi32.NewProc("CloseEventLog")
+	procReadEventLogW             = modadvapi32.NewProc("ReadEventLogW")
+	procClearEventLog             = modadvapi32.NewProc("ClearEventLogW")
+	procGetNumberOfEventLogRecords = modadvapi32.NewProc("GetNumberOfEventLogRecords")
+	procGetOldestEventLogRecord    = modadvapi32.NewProc("GetOldestEventLogRecord")
+)
+
+// eventLogRecord mirrors the fixed-size portion of the Win32 EVENTLOGRECORD
+// structure. The variable-length fields that follow it in the buffer
+// (SourceName, Computer, strings, user SID, and raw data) are parsed
+// separately in parseEventLogRecords.
+type eventLogRecord struct {
+	Length              uint32
+	Reserved            uint32
+	RecordNumber        uint32
+	TimeGenerated       uint32
+	TimeWritten         uint32
+	EventID             uint32
+	EventType           uint16
+	NumStrings          uint16
+	EventCategory       uint16
+	ReservedFlags       uint16
+	ClosingRecordNumber uint32
+	StringOffset        uint32
+	UserSidLength       uint32
+	UserSidOffset       uint32
+	DataLength          uint32
+	DataOffset          uint32
+}
+
+// parseEventLogRecords parses a buffer returned by ReadEventLog into zero
+// or more Records. Each record in the buffer is prefixed and suffixed by
+// its own Length field, allowing records to be walked sequentially.
+func parseEventLogRecords(buf []byte) ([]Record, error) {
+	var records []Record
+
+	for offset := 0; offset < len(buf); {
+		if offset+binary.Size(eventLogRecord{}) > len(buf) {
+			return nil, fmt.Errorf("truncated EVENTLOGRECORD at offset %d", offset)
+		}
+
+		var raw eventLogRecord
+		reader := bytes.NewReader(buf[offset:])
+		if err := binary.Read(reader, binary.LittleEndian, &raw); err != nil {
+			return nil, fmt.Errorf("failed reading EVENTLOGRECORD: %w", err)
+		}
+		if raw.Length == 0 {
+			break
+		}
+
+		recordBuf := buf[offset : offset+int(raw.Length)]
+		sourceName, computerName := parseEventLogRecordNames(recordBuf)
+		message, err := formatEventLogMessage(sourceName, raw, recordBuf)
+		if err != nil {
+			message = err.Error()
+		}
+
+		records = append(records, Record{
+			RecordNumber: uint64(raw.RecordNumber),
+			EventID:      raw.EventID &^ 0xFFFF0000, // strip the qualifier bits.
+			EventType:    raw.EventType,
+			Message:      message,
+			ProviderName: sourceName,
+			ComputerName: computerName,
+		})
+
+		offset += int(raw.Length)
+	}
+
+	return records, nil
+}
+
+// parseEventLogRecordNames extracts the NUL-terminated SourceName and
+// Computer strings that immediately follow the fixed eventLogRecord
+// header.
+func parseEventLogRecordNames(recordBuf []byte) (sourceName, computerName string) {
+	headerSize := binary.Size(eventLogRecord{})
+	rest := recordBuf[headerSize:]
+
+	sourceName, n := utf16BytesToStringNul(rest)
+	computerName, _ = utf16BytesToStringNul(rest[n:])
+	return sourceName, computerName
+}
+
+// utf16BytesToStringNul decodes a NUL-terminated UTF-16LE string from buf
+// and returns the decoded string along with the byte length consumed
+// (including the terminating NUL).
+func utf16BytesToStringNul(buf []byte) (string, int) {
+	u16 := make([]uint16, 0, len(buf)/2)
+	for i := 0; i+1 < len(buf); i += 2 {
+		c := uint16(buf[i]) | uint16(buf[i+1])<<8
+		if c == 0 {
+			return syscall.UTF16ToString(u16), i + 2
+		}
+		u16 = append(u16, c)
+	}
+	return syscall.UTF16ToString(u16), len(buf)
+}
+
+// formatEventLogMessage renders the message for a single record by
+// looking up the EventMessageFile(s) registered for sourceName and
+// formatting the insert strings carried in recordBuf. If no message file
+// can resolve the event ID, the noMessageFile fallback is used (mirroring
+// the behavior of Windows' own Event Viewer).
+func formatEventLogMessage(sourceName string, raw eventLogRecord, recordBuf []byte) (string, error) {
+	inserts := parseInsertStrings(raw, recordBuf)
+
+	msg, err := lookupAndFormatMessage(sourceName, raw.EventID, inserts)
+	if err == nil {
+		return msg, nil
+	}
+
+	return fmt.Sprintf(noMessageFile, raw.EventID&^0xFFFF0000, sourceName,
+		joinInsertStrings(inserts)), nil
+}
+
+// parseInsertStrings splits the NUL-terminated, NUL-separated block of
+// insert strings out of recordBuf using the record's StringOffset and
+// NumStrings fields.
+func parseInsertStrings(raw eventLogRecord, recordBuf []byte) []string {
+	if raw.NumStrings == 0 || int(raw.StringOffset) >= len(recordBuf) {
+		return nil
+	}
+
+	buf := recordBuf[raw.StringOffset:]
+	strings := make([]string, 0, raw.NumStrings)
+	for i := uint16(0); i < raw.NumStrings; i++ {
+		s, n := utf16BytesToStringNul(buf)
+		strings = append(strings, s)
+		buf = buf[n:]
+	}
+	return strings
+}
+
+func joinInsertStrings(inserts []string) string {
+	var buf bytes.Buffer
+	for i, s := range inserts {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(s)
+	}
+	return buf.String()
+}
+
+// lookupAndFormatMessage resolves the message template for eventID from
+// the EventMessageFile(s) registered under sourceName (a semicolon
+// separated list is searched in order) and formats it with inserts.
+//
+// This is implemented in terms of FormatMessage against each candidate
+// module in turn, matching the resolution order Windows itself uses.
+func lookupAndFormatMessage(sourceName string, eventID uint32, inserts []string) (string, error) {
+	files, err := eventMessageFiles(sourceName)
+	if err != nil || len(files) == 0 {
+		return "", fmt.Errorf("no EventMessageFile registered for source '%v'", sourceName)
+	}
+
+	var lastErr error
+	for _, file := range files {
+		msg, err := formatMessageFromModule(file, eventID, inserts)
+		if err == nil {
+			return msg, nil
+		}
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// formatMessageFromModule loads module (an EventMessageFile path, which
+// may contain unexpanded environment variables) and formats eventID using
+// FormatMessage with the given insert strings.
+func formatMessageFromModule(module string, eventID uint32, inserts []string) (string, error) {
+	expanded, err := syscall.ExpandEnvironmentStrings(module)
+	if err != nil {
+		expanded = module
+	}
+
+	h, err := syscall.LoadLibraryEx(expanded, 0,
+		0x00000002| // LOAD_LIBRARY_AS_DATAFILE
+			0x00000020) // LOAD_LIBRARY_AS_IMAGE_RESOURCE
+	if err != nil {
+		return "", err
+	}
+	defer syscall.FreeLibrary(h)
+
+	return formatMessageFromHandle(Handle(h), eventID, inserts)
+}
+
+// formatMessageFromHandle calls FormatMessage against the loaded module
+// handle to render eventID's message template with inserts substituted.
+func formatMessageFromHandle(handle Handle, eventID uint32, inserts []string) (string, error) {
+	const (
+		formatMessageFromHmodule   = 0x00000800
+		formatMessageArgumentArray = 0x00002000
+		formatMessageAllocateBuf   = 0x00000100
+	)
+
+	argv := make([]*uint16, len(inserts))
+	for i, s := range inserts {
+		ptr, err := syscall.UTF16PtrFromString(s)
+		if err != nil {
+			return "", err
+		}
+		argv[i] = ptr
+	}
+
+	var argvPtr uintptr
+	if len(argv) > 0 {
+		argvPtr = uintptr(unsafe.Pointer(&argv[0]))
+	}
+
+	var buf *uint16
+	r1, _, e1 := procFormatMessageW.Call(
+		uintptr(formatMessageFromHmodule|formatMessageArgumentArray|formatMessageAllocateBuf),
+		uintptr(handle),
+		uintptr(eventID),
+		0, // dwLanguageId: neutral, falls back based on the system locale.
+		uintptr(unsafe.Pointer(&buf)),
+		0,
+		argvPtr,
+	)
+	if r1 == 0 {
+		return "", fmt.Errorf("FormatMessage failed for event ID %d: %w", eventID, e1)
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(buf)))
+
+	return syscall.UTF16ToString((*[1 << 20]uint16)(unsafe.Pointer(buf))[:]), nil
+}
+
+var (
+	modkernel32        = syscall.NewLazyDLL("kernel32.dll")
+	procFormatMessageW = modkernel32.NewProc("FormatMessageW")
+	procLocalFree      = modkernel32.NewProc("LocalFree")
+)
+
+// eventMessageFiles returns the EventMessageFile paths registered for
+// sourceName under
+// HKLM\SYSTEM\CurrentControlSet\Services\EventLog\Application\<sourceName>,
+// split on ';' (Windows allows a semicolon separated search list).
+func eventMessageFiles(sourceName string) ([]string, error) {
+	const regPath = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
+	return readSemicolonSeparatedRegValue(regPath+sourceName, "EventMessageFile")
+}
@@ -0,0 +1,231 @@
+// +build windows
+
+package eventlog
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// evtQuery flags (EVT_QUERY_FLAGS).
+const (
+	evtQueryChannelPath = 0x1
+	evtQueryFilePath    = 0x2
+)
+
+// EvtFormatMessage flags (EVT_FORMAT_MESSAGE_FLAGS). Only the ones used by
+// this package are declared.
+const (
+	evtFormatMessageEvent = 1
+)
+
+// errNoMoreItems is returned by EvtNext (as ERROR_NO_MORE_ITEMS) once all
+// currently available events have been consumed.
+var errNoMoreItems = errors.New("no more items")
+
+const errorNoMoreItems = syscall.Errno(259)
+
+var modwevtapi = syscall.NewLazyDLL("wevtapi.dll")
+
+var (
+	procEvtQuery         = modwevtapi.NewProc("EvtQuery")
+	procEvtSubscribe     = modwevtapi.NewProc("EvtSubscribe")
+	procEvtNext          = modwevtapi.NewProc("EvtNext")
+	procEvtClose         = modwevtapi.NewProc("EvtClose")
+	procEvtRender        = modwevtapi.NewProc("EvtRender")
+	procEvtFormatMessage = modwevtapi.NewProc("EvtFormatMessage")
+)
+
+// evtQuery opens channel (or an .evtx file path, when flags includes
+// evtQueryFilePath) scoped to the given XPath query.
+func evtQuery(channel, query string, flags uint32) (eventHandle, error) {
+	channelPtr, err := syscall.UTF16PtrFromString(channel)
+	if err != nil {
+		return 0, err
+	}
+	queryPtr, err := syscall.UTF16PtrFromString(query)
+	if err != nil {
+		return 0, err
+	}
+
+	r1, _, e1 := procEvtQuery.Call(
+		0, // session: nil for the local computer.
+		uintptr(unsafe.Pointer(channelPtr)),
+		uintptr(unsafe.Pointer(queryPtr)),
+		uintptr(flags),
+	)
+	if r1 == 0 {
+		return 0, fmt.Errorf("EvtQuery failed: %w", e1)
+	}
+	return eventHandle(r1), nil
+}
+
+// createResetEvent creates a manual-reset Win32 event object for use as
+// the signal event passed to EvtSubscribe.
+func createResetEvent() (syscall.Handle, error) {
+	return syscall.CreateEvent(nil, 1, 0, nil)
+}
+
+// EVT_SUBSCRIBE_FLAGS origin values accepted by evtSubscribe's flags
+// parameter.
+const (
+	// evtSubscribeToFutureEvents delivers only events logged after the
+	// subscription is created; it ignores any EventRecordID predicate in
+	// the query.
+	evtSubscribeToFutureEvents = 1
+
+	// evtSubscribeStartAtOldestRecord delivers every event currently in
+	// the channel before switching to live delivery, honoring an
+	// EventRecordID predicate in the query so resuming after a known
+	// record number only redelivers newer events.
+	evtSubscribeStartAtOldestRecord = 2
+)
+
+// evtSubscribe subscribes to events on channel matching query using the
+// given EVT_SUBSCRIBE_FLAGS origin (evtSubscribeToFutureEvents or
+// evtSubscribeStartAtOldestRecord). signalEvent is set whenever new
+// events become available for EvtNext to retrieve.
+func evtSubscribe(channel, query string, signalEvent syscall.Handle, flags uint32) (eventHandle, error) {
+	channelPtr, err := syscall.UTF16PtrFromString(channel)
+	if err != nil {
+		return 0, err
+	}
+	queryPtr, err := syscall.UTF16PtrFromString(query)
+	if err != nil {
+		return 0, err
+	}
+
+	r1, _, e1 := procEvtSubscribe.Call(
+		0, // session: nil for the local computer.
+		uintptr(signalEvent),
+		uintptr(unsafe.Pointer(channelPtr)),
+		uintptr(unsafe.Pointer(queryPtr)),
+		0, // bookmark: nil; resume is handled via evtSubscribeStartAtOldestRecord plus the query's EventRecordID predicate.
+		0, // context
+		0, // callback: nil, pull mode via the signal event.
+		uintptr(flags),
+	)
+	if r1 == 0 {
+		return 0, fmt.Errorf("EvtSubscribe failed: %w", e1)
+	}
+	return eventHandle(r1), nil
+}
+
+// evtNext retrieves up to count events from a query or subscription
+// handle. It returns errNoMoreItems when no events are currently
+// available (not a failure).
+func evtNext(handle eventHandle, count int) ([]syscall.Handle, error) {
+	handles := make([]syscall.Handle, count)
+	var numReturned uint32
+
+	r1, _, e1 := procEvtNext.Call(
+		uintptr(handle),
+		uintptr(count),
+		uintptr(unsafe.Pointer(&handles[0])),
+		0, // timeout: 0 so EvtNext returns immediately instead of blocking for new events.
+		0,
+		uintptr(unsafe.Pointer(&numReturned)),
+	)
+	if r1 == 0 {
+		if e1 == errorNoMoreItems {
+			return nil, errNoMoreItems
+		}
+		return nil, fmt.Errorf("EvtNext failed: %w", e1)
+	}
+	return handles[:numReturned], nil
+}
+
+// evtClose closes any EVT_HANDLE (query, subscription, or event).
+func evtClose(handle eventHandle) error {
+	r1, _, e1 := procEvtClose.Call(uintptr(handle))
+	if r1 == 0 {
+		return fmt.Errorf("EvtClose failed: %w", e1)
+	}
+	return nil
+}
+
+// evtRenderXML renders the event referenced by handle to its XML form.
+// buf is reused across calls (and grown as needed) to avoid reallocating
+// on every event; it is sized in UTF-16 code units (WCHARs), matching
+// what EvtRender expects and reports via bufferUsed.
+func evtRenderXML(handle eventHandle, buf *[]uint16) (string, error) {
+	const evtRenderEventXml = 1
+
+	if len(*buf) == 0 {
+		*buf = make([]uint16, 2048)
+	}
+
+	for {
+		var bufferUsed, propertyCount uint32
+		bufferSizeBytes := uint32(len(*buf)) * 2 // EvtRender's size param is in bytes.
+
+		r1, _, e1 := procEvtRender.Call(
+			0, // context: nil for rendering to XML.
+			uintptr(handle),
+			uintptr(evtRenderEventXml),
+			uintptr(bufferSizeBytes),
+			uintptr(unsafe.Pointer(&(*buf)[0])),
+			uintptr(unsafe.Pointer(&bufferUsed)),
+			uintptr(unsafe.Pointer(&propertyCount)),
+		)
+		if r1 != 0 {
+			// bufferUsed is documented in bytes for EvtRender (unlike
+			// EvtFormatMessage, whose BufferUsed is in WCHARs).
+			return syscall.UTF16ToString((*buf)[:bufferUsed/2]), nil
+		}
+
+		const errorInsufficientBuffer = syscall.Errno(122)
+		if e1 != errorInsufficientBuffer {
+			return "", fmt.Errorf("EvtRender failed: %w", e1)
+		}
+
+		// bufferUsed is the required size in bytes; grow in WCHARs.
+		*buf = make([]uint16, (bufferUsed/2)+1)
+	}
+}
+
+// evtFormatMessage formats the message for the event referenced by
+// handle using the given EVT_FORMAT_MESSAGE_FLAGS.
+//
+// EvtFormatMessage's BufferUsed out-parameter is documented ambiguously:
+// despite the function accepting BufferSize in WCHARs, some versions of
+// the docs imply bytes. It is in fact always WCHARs (UTF-16 code units),
+// matching BufferSize. Passing a byte count here under-allocates the
+// buffer by half and silently truncates any message that needs more than
+// one grow iteration to render (observed with event messages over ~4 KB
+// on Windows Server 2022), so buffer sizes are tracked in uint16 (WCHAR)
+// units throughout this function.
+func evtFormatMessage(handle eventHandle, flags uint32) (string, error) {
+	bufSizeChars := uint32(512)
+
+	for {
+		buf := make([]uint16, bufSizeChars)
+		var bufferUsed uint32
+
+		r1, _, e1 := procEvtFormatMessage.Call(
+			0, // publisherMetadata: nil, resolved automatically from the event.
+			uintptr(handle),
+			0, // messageID: unused for EvtFormatMessageEvent.
+			0, // valueCount
+			0, // values
+			uintptr(flags),
+			uintptr(bufSizeChars), // BufferSize is in WCHARs.
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&bufferUsed)), // BufferUsed is also in WCHARs.
+		)
+		if r1 != 0 {
+			return syscall.UTF16ToString(buf[:bufferUsed]), nil
+		}
+
+		const errorInsufficientBuffer = syscall.Errno(122)
+		if e1 != errorInsufficientBuffer {
+			return "", fmt.Errorf("EvtFormatMessage failed: %w", e1)
+		}
+
+		// bufferUsed is the required size in WCHARs; grow directly, no
+		// byte<->char conversion needed (unlike EvtRender above).
+		bufSizeChars = bufferUsed
+	}
+}
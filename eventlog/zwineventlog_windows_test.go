@@ -0,0 +1,76 @@
+// +build windows
+
+package eventlog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	elog "github.com/andrewkroh/sys/windows/svc/eventlog"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEvtFormatMessageLongMessage verifies that messages whose rendered
+// length exceeds the initial buffer are not truncated. EvtFormatMessage's
+// BufferUsed out-parameter is in WCHARs (not bytes); using the wrong unit
+// for the regrow allocates a buffer half the required size, truncating
+// any message that needs to grow past the first attempt.
+//
+// This test logs an event whose formatted message is over 4 KB (longer
+// than any single-pass buffer this package allocates) and asserts the
+// full message comes back.
+func TestEvtFormatMessageLongMessage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode.")
+	}
+	configureLogp()
+
+	const (
+		testProviderName = "Winlogbeat"
+		testSourceName   = "EvtFormatMessage Long Message Test"
+		testEventID      = uint32(1)
+	)
+	longInsert := strings.Repeat("A", 4500)
+
+	log, err := initLog(testProviderName, testSourceName, eventCreateMsgFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := uninstallLog(testProviderName, testSourceName, log); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := log.Report(elog.Info, testEventID, []string{longInsert}); err != nil {
+		t.Fatal(err)
+	}
+
+	queryHandle, err := evtQuery("Application",
+		fmt.Sprintf("*[System[Provider[@Name='%s'] and EventID=%d]]", testSourceName, testEventID),
+		evtQueryChannelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer evtClose(queryHandle)
+
+	eventHandles, err := evtNext(queryHandle, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eventHandles) != 1 {
+		t.Fatalf("expected to find 1 event, found %d", len(eventHandles))
+	}
+	defer evtClose(eventHandle(eventHandles[0]))
+
+	message, err := evtFormatMessage(eventHandle(eventHandles[0]), evtFormatMessageEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, len(message) > 4096,
+		"expected a message longer than 4096 characters, got %d", len(message))
+	assert.Contains(t, message, longInsert,
+		"message was truncated before the end of the long insert string")
+}
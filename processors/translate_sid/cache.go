@@ -0,0 +1,70 @@
+package translate_sid
+
+import "sync"
+
+// resolution is the resolved account identity for a single SID.
+type resolution struct {
+	Name   string
+	Domain string
+	Type   SIDType
+}
+
+// cache is an in-memory LRU of SID string -> resolution, used to avoid
+// calling LookupAccountSid again for a SID this processor has already
+// resolved.
+type cache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]resolution
+	order    []string // insertion/access order, oldest first.
+}
+
+func newCache(capacity int) *cache {
+	return &cache{
+		capacity: capacity,
+		entries:  make(map[string]resolution, capacity),
+	}
+}
+
+// get returns the cached resolution for sid, if present.
+func (c *cache) get(sid string) (resolution, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	r, ok := c.entries[sid]
+	if ok {
+		c.touch(sid)
+	}
+	return r, ok
+}
+
+// put records the resolution for sid, evicting the least recently used
+// entry if the cache is at capacity.
+func (c *cache) put(sid string, r resolution) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.entries[sid]; !exists {
+		if c.capacity > 0 && len(c.order) >= c.capacity {
+			oldest := c.order[0]
+			c.order = c.order[1:]
+			delete(c.entries, oldest)
+		}
+		c.order = append(c.order, sid)
+	} else {
+		c.touch(sid)
+	}
+	c.entries[sid] = r
+}
+
+// touch moves sid to the end of c.order (most recently used). Must be
+// called with c.mutex held.
+func (c *cache) touch(sid string) {
+	for i, s := range c.order {
+		if s == sid {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, sid)
+}
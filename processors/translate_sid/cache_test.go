@@ -0,0 +1,43 @@
+package translate_sid
+
+import "testing"
+
+func TestCacheGetPut(t *testing.T) {
+	c := newCache(2)
+
+	if _, ok := c.get("S-1-5-21-1"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	c.put("S-1-5-21-1", resolution{Name: "alice", Domain: "CONTOSO", Type: SIDTypeUser})
+
+	r, ok := c.get("S-1-5-21-1")
+	if !ok {
+		t.Fatal("expected hit after put")
+	}
+	if r.Name != "alice" || r.Domain != "CONTOSO" || r.Type != SIDTypeUser {
+		t.Fatalf("unexpected resolution: %+v", r)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCache(2)
+
+	c.put("sid-1", resolution{Name: "one"})
+	c.put("sid-2", resolution{Name: "two"})
+
+	// Touch sid-1 so sid-2 becomes the least recently used entry.
+	c.get("sid-1")
+
+	c.put("sid-3", resolution{Name: "three"})
+
+	if _, ok := c.get("sid-2"); ok {
+		t.Fatal("expected sid-2 to have been evicted")
+	}
+	if _, ok := c.get("sid-1"); !ok {
+		t.Fatal("expected sid-1 to still be cached")
+	}
+	if _, ok := c.get("sid-3"); !ok {
+		t.Fatal("expected sid-3 to be cached")
+	}
+}
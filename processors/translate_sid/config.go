@@ -0,0 +1,23 @@
+package translate_sid
+
+// Config controls which event fields containing a SID string (e.g.
+// "S-1-5-21-...") get resolved to an account name, domain, and SID type.
+type Config struct {
+	// Fields maps each source field path holding a SID string (e.g.
+	// "winlog.event_data.TargetUserSid") to the target field path the
+	// resolved account name is written to. The domain and type are
+	// written alongside it as "<target>_domain" and "<target>_type".
+	Fields map[string]string `config:"fields"`
+
+	// CacheSize bounds the number of resolved SIDs kept in memory. It
+	// defaults to defaultCacheSize.
+	CacheSize int `config:"cache_size"`
+
+	// IgnoreFailure controls whether a SID that fails to resolve (e.g.
+	// a deleted account) is silently left alone or causes the event to
+	// be dropped by the processor chain.
+	IgnoreFailure bool `config:"ignore_failure"`
+}
+
+// defaultCacheSize is used when Config.CacheSize is unset.
+const defaultCacheSize = 10000
@@ -0,0 +1,106 @@
+// Package translate_sid implements a winlogbeat processor that resolves
+// the Windows SIDs found in configured event fields (e.g.
+// TargetUserSid, SubjectUserSid) to an account name, domain, and SID
+// type, caching results to avoid repeatedly hitting the LSA for the same
+// SID.
+package translate_sid
+
+import (
+	"fmt"
+
+	"github.com/elastic/libbeat/common"
+	"github.com/elastic/libbeat/logp"
+)
+
+// Processor resolves the SIDs found at Config.Fields into their account
+// name, domain, and type.
+type Processor struct {
+	config Config
+	cache  *cache
+
+	// lookup resolves a single SID string. It is lookupAccountSID by
+	// default; tests substitute a fake to run without a live LSA.
+	lookup func(sid string) (name, domain string, sidType SIDType, err error)
+}
+
+// New creates a Processor from c.
+func New(c Config) (*Processor, error) {
+	if len(c.Fields) == 0 {
+		return nil, fmt.Errorf("translate_sid requires at least one entry in 'fields'")
+	}
+
+	size := c.CacheSize
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+
+	return &Processor{config: c, cache: newCache(size), lookup: lookupAccountSID}, nil
+}
+
+// Run resolves every configured field present in event and writes the
+// resolved name/domain/type alongside the configured target field. It
+// batches the lookups for an event: each distinct SID value is only
+// resolved once, even if it appears under more than one configured field.
+func (p *Processor) Run(event common.MapStr) (common.MapStr, error) {
+	resolved := make(map[string]resolution, len(p.config.Fields))
+
+	for field, target := range p.config.Fields {
+		sid, ok := getString(event, field)
+		if !ok {
+			continue
+		}
+
+		r, ok := resolved[sid]
+		if !ok {
+			var err error
+			r, err = p.resolve(sid)
+			if err != nil {
+				logp.Debug("translate_sid", "failed to resolve SID '%v': %v", sid, err)
+				if !p.config.IgnoreFailure {
+					return event, fmt.Errorf("failed to resolve SID '%v' from field '%v': %w", sid, field, err)
+				}
+				continue
+			}
+			resolved[sid] = r
+		}
+
+		putString(event, target, r.Name)
+		putString(event, target+"_domain", r.Domain)
+		putString(event, target+"_type", r.Type.String())
+	}
+
+	return event, nil
+}
+
+// resolve returns the cached resolution for sid, looking it up via
+// LookupAccountSid and caching the result on a miss.
+func (p *Processor) resolve(sid string) (resolution, error) {
+	if r, ok := p.cache.get(sid); ok {
+		return r, nil
+	}
+
+	name, domain, sidType, err := p.lookup(sid)
+	if err != nil {
+		return resolution{}, err
+	}
+
+	r := resolution{Name: name, Domain: domain, Type: sidType}
+	p.cache.put(sid, r)
+	return r, nil
+}
+
+// getString reads a dotted field path out of event as a string.
+func getString(event common.MapStr, field string) (string, bool) {
+	v, err := event.GetValue(field)
+	if err != nil {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// putString writes value to a dotted field path in event, creating any
+// intermediate objects as needed.
+func putString(event common.MapStr, field, value string) {
+	event.Put(field, value)
+}
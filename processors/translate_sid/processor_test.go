@@ -0,0 +1,137 @@
+package translate_sid
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/elastic/libbeat/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLookup returns a deterministic resolution for "S-1-5-21-alice" and
+// fails for everything else, so tests don't depend on a live LSA.
+func fakeLookup(calls *int) func(string) (string, string, SIDType, error) {
+	return func(sid string) (string, string, SIDType, error) {
+		*calls++
+		if sid == "S-1-5-21-alice" {
+			return "alice", "CONTOSO", SIDTypeUser, nil
+		}
+		return "", "", 0, fmt.Errorf("no such account: %v", sid)
+	}
+}
+
+func TestProcessorRunResolvesField(t *testing.T) {
+	calls := 0
+	p, err := New(Config{Fields: map[string]string{
+		"winlog.event_data.TargetUserSid": "user",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.lookup = fakeLookup(&calls)
+
+	event := common.MapStr{
+		"winlog": common.MapStr{
+			"event_data": common.MapStr{
+				"TargetUserSid": "S-1-5-21-alice",
+			},
+		},
+	}
+
+	event, err = p.Run(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, _ := event.GetValue("user")
+	domain, _ := event.GetValue("user_domain")
+	sidType, _ := event.GetValue("user_type")
+
+	assert.Equal(t, "alice", name)
+	assert.Equal(t, "CONTOSO", domain)
+	assert.Equal(t, "User", sidType)
+}
+
+func TestProcessorRunCachesRepeatedSID(t *testing.T) {
+	calls := 0
+	p, err := New(Config{Fields: map[string]string{
+		"a": "a_user",
+		"b": "b_user",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.lookup = fakeLookup(&calls)
+
+	event := common.MapStr{
+		"a": "S-1-5-21-alice",
+		"b": "S-1-5-21-alice",
+	}
+
+	if _, err := p.Run(event); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, calls, "the same SID appearing twice in one event should only be resolved once")
+
+	if _, err := p.Run(event); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, calls, "a SID already resolved in a prior Run should come from the cache")
+}
+
+func TestProcessorRunMissingFieldIsSkipped(t *testing.T) {
+	calls := 0
+	p, err := New(Config{Fields: map[string]string{
+		"winlog.event_data.TargetUserSid": "user",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.lookup = fakeLookup(&calls)
+
+	event := common.MapStr{}
+	event, err = p.Run(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = event.GetValue("user")
+	assert.Error(t, err, "no field should have been written when the source field is absent")
+	assert.Equal(t, 0, calls)
+}
+
+func TestProcessorRunIgnoreFailure(t *testing.T) {
+	calls := 0
+	p, err := New(Config{
+		Fields:        map[string]string{"sid": "user"},
+		IgnoreFailure: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.lookup = fakeLookup(&calls)
+
+	event := common.MapStr{"sid": "S-1-5-21-unknown"}
+	event, err = p.Run(event)
+	assert.NoError(t, err)
+
+	_, err = event.GetValue("user")
+	assert.Error(t, err)
+}
+
+func TestProcessorRunFailurePropagatesByDefault(t *testing.T) {
+	calls := 0
+	p, err := New(Config{Fields: map[string]string{"sid": "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.lookup = fakeLookup(&calls)
+
+	_, err = p.Run(common.MapStr{"sid": "S-1-5-21-unknown"})
+	assert.Error(t, err)
+}
+
+func TestNewRequiresFields(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package translate_sid
+
+import (
+	"fmt"
+
+	"github.com/elastic/libbeat/common"
+	"github.com/elastic/libbeat/processors"
+)
+
+// pluginName is the value used for this processor's "type" in the
+// beat's processors config list (e.g. `- translate_sid: {fields: ...}`).
+const pluginName = "translate_sid"
+
+func init() {
+	processors.RegisterPlugin(pluginName, newFromConfig)
+}
+
+// newFromConfig adapts New to the processors.Constructor signature
+// expected by the libbeat processor registry, decoding c with the same
+// "config" struct tags Config already declares.
+func newFromConfig(c *common.Config) (processors.Processor, error) {
+	config := Config{CacheSize: defaultCacheSize}
+	if err := c.Unpack(&config); err != nil {
+		return nil, fmt.Errorf("failed to unpack %v config: %w", pluginName, err)
+	}
+	return New(config)
+}
@@ -0,0 +1,77 @@
+// +build windows
+
+package translate_sid
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modadvapi32 = syscall.NewLazyDLL("advapi32.dll")
+
+	procConvertStringSidToSidW = modadvapi32.NewProc("ConvertStringSidToSidW")
+	procLookupAccountSidW      = modadvapi32.NewProc("LookupAccountSidW")
+	procLocalFree              = syscall.NewLazyDLL("kernel32.dll").NewProc("LocalFree")
+)
+
+// lookupAccountSID resolves sidString (e.g. "S-1-5-21-...") to its
+// account name, domain, and SID type via LookupAccountSidW.
+func lookupAccountSID(sidString string) (name, domain string, sidType SIDType, err error) {
+	sid, err := stringToSid(sidString)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid SID '%v': %w", sidString, err)
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(sid)))
+
+	var nameBuf, domainBuf []uint16
+	var nameLen, domainLen uint32 = 256, 256
+	var use uint32
+
+	for {
+		nameBuf = make([]uint16, nameLen)
+		domainBuf = make([]uint16, domainLen)
+
+		r1, _, e1 := procLookupAccountSidW.Call(
+			0, // lpSystemName: nil for the local computer.
+			uintptr(unsafe.Pointer(sid)),
+			uintptr(unsafe.Pointer(&nameBuf[0])),
+			uintptr(unsafe.Pointer(&nameLen)),
+			uintptr(unsafe.Pointer(&domainBuf[0])),
+			uintptr(unsafe.Pointer(&domainLen)),
+			uintptr(unsafe.Pointer(&use)),
+		)
+		if r1 != 0 {
+			return syscall.UTF16ToString(nameBuf[:nameLen]),
+				syscall.UTF16ToString(domainBuf[:domainLen]),
+				SIDType(use), nil
+		}
+
+		const errorInsufficientBuffer = syscall.Errno(122)
+		if e1 != errorInsufficientBuffer {
+			return "", "", 0, fmt.Errorf("LookupAccountSid failed for '%v': %w", sidString, e1)
+		}
+		// nameLen/domainLen were updated in place with the required size.
+	}
+}
+
+// stringToSid converts a SID string (e.g. "S-1-5-21-...") to a *SID via
+// ConvertStringSidToSidW. The returned pointer must be freed with
+// LocalFree.
+func stringToSid(sidString string) (unsafe.Pointer, error) {
+	strPtr, err := syscall.UTF16PtrFromString(sidString)
+	if err != nil {
+		return nil, err
+	}
+
+	var sid unsafe.Pointer
+	r1, _, e1 := procConvertStringSidToSidW.Call(
+		uintptr(unsafe.Pointer(strPtr)),
+		uintptr(unsafe.Pointer(&sid)),
+	)
+	if r1 == 0 {
+		return nil, e1
+	}
+	return sid, nil
+}
@@ -0,0 +1,50 @@
+package translate_sid
+
+// SIDType mirrors the Win32 SID_NAME_USE enumeration returned by
+// LookupAccountSid, identifying what kind of account a SID refers to.
+type SIDType int
+
+// SID_NAME_USE values, as defined by winnt.h.
+const (
+	SIDTypeUser SIDType = iota + 1
+	SIDTypeGroup
+	SIDTypeDomain
+	SIDTypeAlias
+	SIDTypeWellKnownGroup
+	SIDTypeDeletedAccount
+	SIDTypeInvalid
+	SIDTypeUnknown
+	SIDTypeComputer
+	SIDTypeLabel
+	SIDTypeLogonSession
+)
+
+// String returns the display name for t (e.g. "User", "WellKnownGroup"),
+// so that the resolved type field renders as text rather than an
+// integer.
+func (t SIDType) String() string {
+	switch t {
+	case SIDTypeUser:
+		return "User"
+	case SIDTypeGroup:
+		return "Group"
+	case SIDTypeDomain:
+		return "Domain"
+	case SIDTypeAlias:
+		return "Alias"
+	case SIDTypeWellKnownGroup:
+		return "WellKnownGroup"
+	case SIDTypeDeletedAccount:
+		return "DeletedAccount"
+	case SIDTypeInvalid:
+		return "Invalid"
+	case SIDTypeComputer:
+		return "Computer"
+	case SIDTypeLabel:
+		return "Label"
+	case SIDTypeLogonSession:
+		return "LogonSession"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,15 @@
+package translate_sid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSIDTypeString(t *testing.T) {
+	assert.Equal(t, "User", SIDTypeUser.String())
+	assert.Equal(t, "Group", SIDTypeGroup.String())
+	assert.Equal(t, "WellKnownGroup", SIDTypeWellKnownGroup.String())
+	assert.Equal(t, "Unknown", SIDType(0).String())
+	assert.Equal(t, "Unknown", SIDType(99).String())
+}
@@ -0,0 +1,175 @@
+// +build windows
+
+// Package winevent enriches eventlog.Record values read from the
+// WinEventLog backend with the human-readable display strings for their
+// raw Level, Opcode, Task, and Keywords fields.
+package winevent
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/elastic/winlogbeat/eventlog"
+)
+
+// cacheSize bounds the number of distinct (provider, flag, value) display
+// strings kept in memory. A single provider rarely defines more than a
+// few dozen distinct Level/Opcode/Task/Keyword values in total, so this
+// comfortably covers even a host logging from hundreds of providers.
+const cacheSize = 4096
+
+type cacheKey struct {
+	provider string
+	flag     eventlog.FormatMessageFlag
+	value    uint64
+}
+
+// cache resolves and caches display strings per provider + raw value. A
+// single mutex guards both the open publisher metadata handles and the
+// resolved strings since lookups are infrequent relative to event volume
+// and each call is already dominated by the EvtFormatMessage syscall.
+type cache struct {
+	mutex sync.Mutex
+
+	metadata map[string]eventlog.PublisherMetadataHandle
+	strings  map[cacheKey]string
+	order    []cacheKey // access order, least recently used first, for LRU eviction.
+}
+
+func newCache() *cache {
+	return &cache{
+		metadata: map[string]eventlog.PublisherMetadataHandle{},
+		strings:  map[cacheKey]string{},
+	}
+}
+
+// defaultCache is shared by every call to EnrichRawValuesWithNames. A
+// package-level cache is appropriate here: publisher metadata handles are
+// cheap to keep open for the life of the process, and the point of the
+// cache is precisely to amortize them across all events from a provider,
+// not just those in one Read() batch.
+var defaultCache = newCache()
+
+func (c *cache) publisherMetadata(provider string) (eventlog.PublisherMetadataHandle, error) {
+	if h, ok := c.metadata[provider]; ok {
+		return h, nil
+	}
+
+	h, err := eventlog.OpenPublisherMetadata(provider)
+	if err != nil {
+		return 0, err
+	}
+	c.metadata[provider] = h
+	return h, nil
+}
+
+func (c *cache) formatValue(provider string, flag eventlog.FormatMessageFlag, value uint64) (string, error) {
+	key := cacheKey{provider, flag, value}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if s, ok := c.strings[key]; ok {
+		for i, k := range c.order {
+			if k == key {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+		c.order = append(c.order, key)
+		return s, nil
+	}
+
+	h, err := c.publisherMetadata(provider)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := eventlog.FormatValue(h, flag, value)
+	if err != nil {
+		return "", err
+	}
+
+	c.put(key, s)
+	return s, nil
+}
+
+// put records key/value, evicting the least recently used entry once
+// cacheSize is exceeded. Must be called with c.mutex held.
+func (c *cache) put(key cacheKey, value string) {
+	if _, exists := c.strings[key]; !exists {
+		c.order = append(c.order, key)
+		if len(c.order) > cacheSize {
+			delete(c.strings, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.strings[key] = value
+}
+
+// EnrichRawValuesWithNames resolves the display strings for each record's
+// LevelRaw, OpcodeRaw, Task, and KeywordsRaw and populates the
+// corresponding Level, Opcode, TaskName, and Keywords fields in place.
+// Records from a provider with no message resources installed (or a value
+// that fails to resolve) are left with their raw fields intact and the
+// corresponding display field empty.
+func EnrichRawValuesWithNames(records []eventlog.Record) {
+	for i := range records {
+		enrich(&records[i])
+	}
+}
+
+func enrich(r *eventlog.Record) {
+	if r.ProviderName == "" {
+		return
+	}
+
+	if s, err := defaultCache.formatValue(r.ProviderName, eventlog.FormatMessageLevel, uint64(r.LevelRaw)); err == nil {
+		r.Level = s
+	}
+
+	if r.OpcodeRaw != nil {
+		if s, err := defaultCache.formatValue(r.ProviderName, eventlog.FormatMessageOpcode, uint64(*r.OpcodeRaw)); err == nil {
+			r.Opcode = s
+		}
+	}
+
+	if s, err := defaultCache.formatValue(r.ProviderName, eventlog.FormatMessageTask, uint64(r.Task)); err == nil {
+		r.TaskName = s
+	}
+
+	r.Keywords = resolveKeywords(r.ProviderName, r.KeywordsRaw)
+}
+
+// resolveKeywords resolves the display string for each bit set in the
+// hex-formatted keywordsRaw bitmask (e.g. "0x8000000000000000").
+func resolveKeywords(provider, keywordsRaw string) []string {
+	var names []string
+	for _, bit := range keywordBits(keywordsRaw) {
+		if s, err := defaultCache.formatValue(provider, eventlog.FormatMessageKeyword, bit); err == nil && s != "" {
+			names = append(names, s)
+		}
+	}
+	return names
+}
+
+// keywordBits parses the hex-formatted keywordsRaw bitmask (e.g.
+// "0x8000000000000000") and returns each individual bit that is set, most
+// significant first, matching how EvtFormatMessage expects to resolve one
+// keyword bit at a time.
+func keywordBits(keywordsRaw string) []uint64 {
+	mask, err := strconv.ParseUint(strings.TrimPrefix(keywordsRaw, "0x"), 16, 64)
+	if err != nil || mask == 0 {
+		return nil
+	}
+
+	var bits []uint64
+	for bit := 63; bit >= 0; bit-- {
+		value := uint64(1) << uint(bit)
+		if mask&value != 0 {
+			bits = append(bits, value)
+		}
+	}
+	return bits
+}
@@ -0,0 +1,21 @@
+// +build windows
+
+package winevent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeywordBits(t *testing.T) {
+	assert.Nil(t, keywordBits(""))
+	assert.Nil(t, keywordBits("0x0"))
+	assert.Nil(t, keywordBits("not-hex"))
+
+	assert.Equal(t, []uint64{0x8000000000000000}, keywordBits("0x8000000000000000"))
+
+	// Highest bit first, matching EvtFormatMessage's own Keywords
+	// documentation ordering.
+	assert.Equal(t, []uint64{0x8000000000000000, 0x10}, keywordBits("0x8000000000000010"))
+}